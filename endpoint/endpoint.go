@@ -0,0 +1,39 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+// TTL is a structured type for a Time-To-Live value used by endpoints.
+type TTL int64
+
+// RecordType identifies the DNS resource record type of an endpoint.
+type RecordType string
+
+// RecordType holds the DNS record type constants understood by external-dns sources.
+const (
+	RecordTypeA     RecordType = "A"
+	RecordTypeAAAA  RecordType = "AAAA"
+	RecordTypeCNAME RecordType = "CNAME"
+	RecordTypeTXT   RecordType = "TXT"
+	RecordTypeSRV   RecordType = "SRV"
+	RecordTypeNS    RecordType = "NS"
+	RecordTypePTR   RecordType = "PTR"
+	RecordTypeMX    RecordType = "MX"
+	RecordTypeNAPTR RecordType = "NAPTR"
+	RecordTypeCAA   RecordType = "CAA"
+	RecordTypeSSHFP RecordType = "SSHFP"
+	RecordTypeTLSA  RecordType = "TLSA"
+)
@@ -0,0 +1,604 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	hostnameAnnotationKey = "external-dns.alpha.kubernetes.io/hostname"
+	targetAnnotationKey   = "external-dns.alpha.kubernetes.io/target"
+	ttlAnnotationKey      = "external-dns.alpha.kubernetes.io/ttl"
+
+	srvRecordTypePriorityAnnotationKey = "external-dns.alpha.kubernetes.io/srv-record-priority"
+	srvRecordTypeWeightAnnotationKey   = "external-dns.alpha.kubernetes.io/srv-record-weight"
+	srvRecordTypePortAnnotationKey     = "external-dns.alpha.kubernetes.io/srv-record-port"
+	srvRecordTypeTargetAnnotationKey   = "external-dns.alpha.kubernetes.io/srv-record-target"
+
+	mxRecordTypePreferenceAnnotationKey = "external-dns.alpha.kubernetes.io/mx-record-preference"
+	mxRecordTypeExchangeAnnotationKey   = "external-dns.alpha.kubernetes.io/mx-record-exchange"
+
+	caaRecordTypeFlagsAnnotationKey = "external-dns.alpha.kubernetes.io/caa-record-flags"
+	caaRecordTypeTagAnnotationKey   = "external-dns.alpha.kubernetes.io/caa-record-tag"
+	caaRecordTypeValueAnnotationKey = "external-dns.alpha.kubernetes.io/caa-record-value"
+
+	naptrRecordTypeOrderAnnotationKey       = "external-dns.alpha.kubernetes.io/naptr-record-order"
+	naptrRecordTypePreferenceAnnotationKey  = "external-dns.alpha.kubernetes.io/naptr-record-preference"
+	naptrRecordTypeFlagsAnnotationKey       = "external-dns.alpha.kubernetes.io/naptr-record-flags"
+	naptrRecordTypeServiceAnnotationKey     = "external-dns.alpha.kubernetes.io/naptr-record-service"
+	naptrRecordTypeRegexpAnnotationKey      = "external-dns.alpha.kubernetes.io/naptr-record-regexp"
+	naptrRecordTypeReplacementAnnotationKey = "external-dns.alpha.kubernetes.io/naptr-record-replacement"
+
+	sshfpRecordTypeAlgorithmAnnotationKey   = "external-dns.alpha.kubernetes.io/sshfp-record-algorithm"
+	sshfpRecordTypeFPTypeAnnotationKey      = "external-dns.alpha.kubernetes.io/sshfp-record-fptype"
+	sshfpRecordTypeFingerprintAnnotationKey = "external-dns.alpha.kubernetes.io/sshfp-record-fingerprint"
+
+	tlsaRecordTypeUsageAnnotationKey        = "external-dns.alpha.kubernetes.io/tlsa-record-usage"
+	tlsaRecordTypeSelectorAnnotationKey     = "external-dns.alpha.kubernetes.io/tlsa-record-selector"
+	tlsaRecordTypeMatchingTypeAnnotationKey = "external-dns.alpha.kubernetes.io/tlsa-record-matching-type"
+	tlsaRecordTypeCertDataAnnotationKey     = "external-dns.alpha.kubernetes.io/tlsa-record-cert-data"
+
+	mxPreferenceMinimum     = 0
+	mxPreferenceMaximum     = 65535
+	caaFlagsMinimum         = 0
+	caaFlagsMaximum         = 255
+	naptrOrderMinimum       = 0
+	naptrOrderMaximum       = 65535
+	naptrPreferenceMinimum  = 0
+	naptrPreferenceMaximum  = 65535
+	sshfpAlgorithmMinimum   = 1
+	sshfpAlgorithmMaximum   = 4
+	sshfpFPTypeMinimum      = 1
+	sshfpFPTypeMaximum      = 2
+	tlsaUsageMinimum        = 0
+	tlsaUsageMaximum        = 3
+	tlsaSelectorMinimum     = 0
+	tlsaSelectorMaximum     = 1
+	tlsaMatchingTypeMinimum = 0
+	tlsaMatchingTypeMaximum = 2
+
+	ttlMinimum  = 1
+	ttlMaximum  = (1 << 32) - 1
+	portMinimum = 0
+	portMaximum = 65535
+)
+
+// TTLPolicy constrains how a TTL annotation is resolved: RoundTo first snaps the requested value to
+// the nearest multiple of itself (e.g. Route53 recommends multiples of 60s), then Min and Max clamp
+// the result, so a clamped value need not be a multiple of RoundTo. Default is used when no TTL
+// annotation is present. A zero RoundTo means no rounding and a zero Default is not applied; a zero
+// Min or Max falls back to the absolute ttlMinimum or ttlMaximum bound.
+type TTLPolicy struct {
+	Min     time.Duration
+	Max     time.Duration
+	Default time.Duration
+	RoundTo time.Duration
+}
+
+// SRVValue holds a single resource record of an SRV RRset.
+type SRVValue struct {
+	Priority int64
+	Weight   int64
+	Port     int64
+	Target   string
+}
+
+// String returns the canonical "priority weight port target" representation of v, suitable for use
+// as a single entry of an endpoint's Targets.
+func (v SRVValue) String() string {
+	return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target)
+}
+
+// getTTLFromAnnotations extracts the optional TTL from the given annotations map. policy may be nil,
+// in which case the value is only checked against the global [ttlMinimum, ttlMaximum] bounds,
+// preserving the original behavior; with a non-nil policy no range error is returned: the value is
+// instead rounded to the nearest multiple of policy.RoundTo and then clamped to
+// [policy.Min, policy.Max] (see applyTTLPolicy), logging a warning whenever the user-specified value
+// is silently adjusted.
+func getTTLFromAnnotations(annotations map[string]string, policy *TTLPolicy) (endpoint.TTL, error) {
+	ttlNotConfigured := endpoint.TTL(0)
+	ttlStr, ok := annotations[ttlAnnotationKey]
+	if !ok {
+		if policy != nil && policy.Default != 0 {
+			return endpoint.TTL(int64(policy.Default.Seconds())), nil
+		}
+		return ttlNotConfigured, nil
+	}
+
+	var ttlSeconds int64
+	if ttlDuration, err := time.ParseDuration(ttlStr); err == nil {
+		ttlSeconds = int64(ttlDuration.Seconds())
+	} else {
+		ttlSeconds, err = strconv.ParseInt(ttlStr, 10, 64)
+		if err != nil {
+			return ttlNotConfigured, fmt.Errorf("%q is not a valid TTL value", ttlStr)
+		}
+	}
+
+	if policy == nil {
+		if ttlSeconds < ttlMinimum || ttlSeconds > ttlMaximum {
+			return ttlNotConfigured, fmt.Errorf("TTL value must be between [%d, %d]", ttlMinimum, ttlMaximum)
+		}
+		return endpoint.TTL(ttlSeconds), nil
+	}
+
+	adjusted := applyTTLPolicy(ttlSeconds, policy)
+	if adjusted != ttlSeconds {
+		log.WithFields(log.Fields{
+			"requestedTTL": ttlSeconds,
+			"adjustedTTL":  adjusted,
+		}).Warn("TTL value adjusted to satisfy TTL policy")
+	}
+
+	return endpoint.TTL(adjusted), nil
+}
+
+// applyTTLPolicy rounds ttlSeconds to the nearest multiple of policy.RoundTo and then clamps it to
+// [policy.Min, policy.Max]. An unset (zero) policy.Min/policy.Max falls back to the absolute
+// [ttlMinimum, ttlMaximum] bounds rather than leaving that side unconstrained, so a policy that only
+// configures RoundTo (e.g. the Route53 "multiple of 60s" case) can never let a TTL through unclamped.
+func applyTTLPolicy(ttlSeconds int64, policy *TTLPolicy) int64 {
+	adjusted := ttlSeconds
+
+	if roundTo := int64(policy.RoundTo.Seconds()); roundTo > 0 && adjusted%roundTo != 0 {
+		adjusted = ((adjusted + roundTo/2) / roundTo) * roundTo
+	}
+
+	min := int64(ttlMinimum)
+	if policy.Min != 0 {
+		min = int64(policy.Min.Seconds())
+	}
+	max := int64(ttlMaximum)
+	if policy.Max != 0 {
+		max = int64(policy.Max.Seconds())
+	}
+
+	if adjusted < min {
+		adjusted = min
+	}
+	if adjusted > max {
+		adjusted = max
+	}
+
+	return adjusted
+}
+
+// getSRVRecordTypeValuesFromAnnotations extracts the priority, weight and port of a single SRV
+// record from the given annotations map.
+func getSRVRecordTypeValuesFromAnnotations(svcName string, annotations map[string]string) (priority int64, weight int64, port int64, err error) {
+	priorityStr, ok := annotations[srvRecordTypePriorityAnnotationKey]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("must specify priority value for SRV record. service %q", svcName)
+	}
+	priority, err = strconv.ParseInt(priorityStr, 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("priorty value must be int number, got %q. service %q", priorityStr, svcName)
+	}
+
+	weightStr, ok := annotations[srvRecordTypeWeightAnnotationKey]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("must specify weight value for SRV record. service %q", svcName)
+	}
+	weight, err = strconv.ParseInt(weightStr, 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("weight value must be int number, got %q. service %q", weightStr, svcName)
+	}
+
+	portStr, ok := annotations[srvRecordTypePortAnnotationKey]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("must specify port value for SRV record. service %q", svcName)
+	}
+	port, err = strconv.ParseInt(portStr, 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("port value must be int number, got %q. service %q", portStr, svcName)
+	}
+	if port < portMinimum || port > portMaximum {
+		return 0, 0, 0, fmt.Errorf("port value must be between [%d, %d], got %q. service %q", portMinimum, portMaximum, portStr, svcName)
+	}
+
+	return priority, weight, port, nil
+}
+
+// getSRVRecordTypeValuesListFromAnnotations extracts one or more SRV records from the given
+// annotations map. Indexed annotations (e.g. "...srv-record-priority.0", "...srv-record-priority.1")
+// are used to describe an SRV RRset with several targets; when none are present it falls back to
+// the legacy single-record annotations. Mixing the legacy and indexed forms is an error.
+//
+// This package has no service.go/ingress.go in this tree, so the service source's emission of one
+// endpoint.Endpoint per returned SRVValue is not wired up here — only the annotation parsing and
+// canonical Targets formatting (see getSRVRecordTypeTargetsFromAnnotations) are implemented.
+func getSRVRecordTypeValuesListFromAnnotations(svcName string, annotations map[string]string) ([]SRVValue, error) {
+	indices, err := indexedSRVAnnotationIndices(annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	legacyPresent := hasLegacySRVAnnotations(annotations)
+	if len(indices) == 0 {
+		if !legacyPresent {
+			return nil, fmt.Errorf("must specify priority value for SRV record. service %q", svcName)
+		}
+		priority, weight, port, err := getSRVRecordTypeValuesFromAnnotations(svcName, annotations)
+		if err != nil {
+			return nil, err
+		}
+		target, ok := annotations[srvRecordTypeTargetAnnotationKey]
+		if !ok {
+			return nil, fmt.Errorf("must specify target value for SRV record. service %q", svcName)
+		}
+		return []SRVValue{{Priority: priority, Weight: weight, Port: port, Target: target}}, nil
+	}
+
+	if legacyPresent {
+		return nil, fmt.Errorf("cannot mix indexed and non-indexed SRV record annotations. service %q", svcName)
+	}
+
+	values := make([]SRVValue, 0, len(indices))
+	for _, idx := range indices {
+		value, err := getIndexedSRVRecordTypeValuesFromAnnotations(svcName, annotations, idx)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// getSRVRecordTypeTargetsFromAnnotations extracts one or more SRV records from the given annotations
+// map, same as getSRVRecordTypeValuesListFromAnnotations, and formats each into its canonical
+// "priority weight port target" Targets entry.
+func getSRVRecordTypeTargetsFromAnnotations(svcName string, annotations map[string]string) ([]string, error) {
+	values, err := getSRVRecordTypeValuesListFromAnnotations(svcName, annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]string, 0, len(values))
+	for _, value := range values {
+		targets = append(targets, value.String())
+	}
+
+	return targets, nil
+}
+
+// getIndexedSRVRecordTypeValuesFromAnnotations extracts a single SRV record at the given index from
+// the indexed annotation form.
+func getIndexedSRVRecordTypeValuesFromAnnotations(svcName string, annotations map[string]string, idx int) (SRVValue, error) {
+	suffix := fmt.Sprintf(".%d", idx)
+
+	priorityStr, ok := annotations[srvRecordTypePriorityAnnotationKey+suffix]
+	if !ok {
+		return SRVValue{}, fmt.Errorf("must specify priority value for SRV record %d. service %q", idx, svcName)
+	}
+	priority, err := strconv.ParseInt(priorityStr, 10, 64)
+	if err != nil {
+		return SRVValue{}, fmt.Errorf("priorty value must be int number, got %q. service %q", priorityStr, svcName)
+	}
+
+	weightStr, ok := annotations[srvRecordTypeWeightAnnotationKey+suffix]
+	if !ok {
+		return SRVValue{}, fmt.Errorf("must specify weight value for SRV record %d. service %q", idx, svcName)
+	}
+	weight, err := strconv.ParseInt(weightStr, 10, 64)
+	if err != nil {
+		return SRVValue{}, fmt.Errorf("weight value must be int number, got %q. service %q", weightStr, svcName)
+	}
+
+	portStr, ok := annotations[srvRecordTypePortAnnotationKey+suffix]
+	if !ok {
+		return SRVValue{}, fmt.Errorf("must specify port value for SRV record %d. service %q", idx, svcName)
+	}
+	port, err := strconv.ParseInt(portStr, 10, 64)
+	if err != nil {
+		return SRVValue{}, fmt.Errorf("port value must be int number, got %q. service %q", portStr, svcName)
+	}
+	if port < portMinimum || port > portMaximum {
+		return SRVValue{}, fmt.Errorf("port value must be between [%d, %d], got %q. service %q", portMinimum, portMaximum, portStr, svcName)
+	}
+
+	target, ok := annotations[srvRecordTypeTargetAnnotationKey+suffix]
+	if !ok {
+		return SRVValue{}, fmt.Errorf("must specify target value for SRV record %d. service %q", idx, svcName)
+	}
+
+	return SRVValue{Priority: priority, Weight: weight, Port: port, Target: target}, nil
+}
+
+// hasLegacySRVAnnotations reports whether any of the un-indexed, single-record SRV annotations are
+// present.
+func hasLegacySRVAnnotations(annotations map[string]string) bool {
+	for _, key := range []string{srvRecordTypePriorityAnnotationKey, srvRecordTypeWeightAnnotationKey, srvRecordTypePortAnnotationKey, srvRecordTypeTargetAnnotationKey} {
+		if _, ok := annotations[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// indexedSRVAnnotationIndices returns the sorted, contiguous list of indices (starting at 0) used by
+// the indexed SRV annotations, or an error if the indices found have a gap.
+func indexedSRVAnnotationIndices(annotations map[string]string) ([]int, error) {
+	seen := map[int]bool{}
+	for _, base := range []string{srvRecordTypePriorityAnnotationKey, srvRecordTypeWeightAnnotationKey, srvRecordTypePortAnnotationKey, srvRecordTypeTargetAnnotationKey} {
+		prefix := base + "."
+		for key := range annotations {
+			if len(key) <= len(prefix) || key[:len(prefix)] != prefix {
+				continue
+			}
+			idx, err := strconv.Atoi(key[len(prefix):])
+			if err != nil {
+				continue
+			}
+			seen[idx] = true
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil, nil
+	}
+
+	indices := make([]int, 0, len(seen))
+	for idx := range seen {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	for i, idx := range indices {
+		if idx != i {
+			return nil, fmt.Errorf("SRV record annotation indices must be contiguous starting at 0, missing index %d", i)
+		}
+	}
+
+	return indices, nil
+}
+
+// recordTypeParser parses the annotations for a particular DNS resource record type into its
+// canonical, space-joined Targets string.
+type recordTypeParser func(svcName string, annotations map[string]string) (string, error)
+
+// recordTypeParsers is the registry of annotation parsers keyed by DNS resource record type. It is
+// consulted by getRecordTypeValuesFromAnnotations for any record type beyond the well-known A/AAAA/
+// CNAME/TXT/SRV handled directly by the sources.
+var recordTypeParsers = map[endpoint.RecordType]recordTypeParser{
+	endpoint.RecordTypeMX:    getMXRecordTypeValuesFromAnnotations,
+	endpoint.RecordTypeCAA:   getCAARecordTypeValuesFromAnnotations,
+	endpoint.RecordTypeNAPTR: getNAPTRRecordTypeValuesFromAnnotations,
+	endpoint.RecordTypeSSHFP: getSSHFPRecordTypeValuesFromAnnotations,
+	endpoint.RecordTypeTLSA:  getTLSARecordTypeValuesFromAnnotations,
+}
+
+// getRecordTypeValuesFromAnnotations looks up the parser registered for recordType and uses it to
+// build the canonical Targets string for the given annotations.
+func getRecordTypeValuesFromAnnotations(recordType endpoint.RecordType, svcName string, annotations map[string]string) (string, error) {
+	parser, ok := recordTypeParsers[recordType]
+	if !ok {
+		return "", fmt.Errorf("no annotation parser registered for record type %q. service %q", recordType, svcName)
+	}
+	return parser(svcName, annotations)
+}
+
+// getMXRecordTypeValuesFromAnnotations extracts the preference and exchange of an MX record from the
+// given annotations map.
+func getMXRecordTypeValuesFromAnnotations(svcName string, annotations map[string]string) (string, error) {
+	preferenceStr, ok := annotations[mxRecordTypePreferenceAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify preference value for MX record. service %q", svcName)
+	}
+	preference, err := strconv.ParseInt(preferenceStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("preference value must be int number, got %q. service %q", preferenceStr, svcName)
+	}
+	if preference < mxPreferenceMinimum || preference > mxPreferenceMaximum {
+		return "", fmt.Errorf("preference value must be between [%d, %d], got %q. service %q", mxPreferenceMinimum, mxPreferenceMaximum, preferenceStr, svcName)
+	}
+
+	exchange, ok := annotations[mxRecordTypeExchangeAnnotationKey]
+	if !ok || exchange == "" {
+		return "", fmt.Errorf("must specify exchange value for MX record. service %q", svcName)
+	}
+
+	return fmt.Sprintf("%d %s", preference, exchange), nil
+}
+
+// getCAARecordTypeValuesFromAnnotations extracts the flags, tag and value of a CAA record from the
+// given annotations map.
+func getCAARecordTypeValuesFromAnnotations(svcName string, annotations map[string]string) (string, error) {
+	flagsStr, ok := annotations[caaRecordTypeFlagsAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify flags value for CAA record. service %q", svcName)
+	}
+	flags, err := strconv.ParseInt(flagsStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("flags value must be int number, got %q. service %q", flagsStr, svcName)
+	}
+	if flags < caaFlagsMinimum || flags > caaFlagsMaximum {
+		return "", fmt.Errorf("flags value must be between [%d, %d], got %q. service %q", caaFlagsMinimum, caaFlagsMaximum, flagsStr, svcName)
+	}
+
+	tag, ok := annotations[caaRecordTypeTagAnnotationKey]
+	if !ok || tag == "" {
+		return "", fmt.Errorf("must specify tag value for CAA record. service %q", svcName)
+	}
+
+	value, ok := annotations[caaRecordTypeValueAnnotationKey]
+	if !ok || value == "" {
+		return "", fmt.Errorf("must specify value value for CAA record. service %q", svcName)
+	}
+
+	return fmt.Sprintf("%d %s %q", flags, tag, value), nil
+}
+
+// getNAPTRRecordTypeValuesFromAnnotations extracts the order, preference, flags, service, regexp and
+// replacement of a NAPTR record from the given annotations map.
+func getNAPTRRecordTypeValuesFromAnnotations(svcName string, annotations map[string]string) (string, error) {
+	orderStr, ok := annotations[naptrRecordTypeOrderAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify order value for NAPTR record. service %q", svcName)
+	}
+	order, err := strconv.ParseInt(orderStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("order value must be int number, got %q. service %q", orderStr, svcName)
+	}
+	if order < naptrOrderMinimum || order > naptrOrderMaximum {
+		return "", fmt.Errorf("order value must be between [%d, %d], got %q. service %q", naptrOrderMinimum, naptrOrderMaximum, orderStr, svcName)
+	}
+
+	preferenceStr, ok := annotations[naptrRecordTypePreferenceAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify preference value for NAPTR record. service %q", svcName)
+	}
+	preference, err := strconv.ParseInt(preferenceStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("preference value must be int number, got %q. service %q", preferenceStr, svcName)
+	}
+	if preference < naptrPreferenceMinimum || preference > naptrPreferenceMaximum {
+		return "", fmt.Errorf("preference value must be between [%d, %d], got %q. service %q", naptrPreferenceMinimum, naptrPreferenceMaximum, preferenceStr, svcName)
+	}
+
+	flags, ok := annotations[naptrRecordTypeFlagsAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify flags value for NAPTR record. service %q", svcName)
+	}
+
+	service, ok := annotations[naptrRecordTypeServiceAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify service value for NAPTR record. service %q", svcName)
+	}
+
+	regexpVal, ok := annotations[naptrRecordTypeRegexpAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify regexp value for NAPTR record. service %q", svcName)
+	}
+
+	replacement, ok := annotations[naptrRecordTypeReplacementAnnotationKey]
+	if !ok || replacement == "" {
+		return "", fmt.Errorf("must specify replacement value for NAPTR record. service %q", svcName)
+	}
+
+	return fmt.Sprintf("%d %d %q %q %q %s", order, preference, flags, service, regexpVal, replacement), nil
+}
+
+// getSSHFPRecordTypeValuesFromAnnotations extracts the algorithm, fingerprint type and fingerprint of
+// an SSHFP record from the given annotations map.
+func getSSHFPRecordTypeValuesFromAnnotations(svcName string, annotations map[string]string) (string, error) {
+	algorithmStr, ok := annotations[sshfpRecordTypeAlgorithmAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify algorithm value for SSHFP record. service %q", svcName)
+	}
+	algorithm, err := strconv.ParseInt(algorithmStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("algorithm value must be int number, got %q. service %q", algorithmStr, svcName)
+	}
+	if algorithm < sshfpAlgorithmMinimum || algorithm > sshfpAlgorithmMaximum {
+		return "", fmt.Errorf("algorithm value must be between [%d, %d], got %q. service %q", sshfpAlgorithmMinimum, sshfpAlgorithmMaximum, algorithmStr, svcName)
+	}
+
+	fpTypeStr, ok := annotations[sshfpRecordTypeFPTypeAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify fptype value for SSHFP record. service %q", svcName)
+	}
+	fpType, err := strconv.ParseInt(fpTypeStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("fptype value must be int number, got %q. service %q", fpTypeStr, svcName)
+	}
+	if fpType < sshfpFPTypeMinimum || fpType > sshfpFPTypeMaximum {
+		return "", fmt.Errorf("fptype value must be between [%d, %d], got %q. service %q", sshfpFPTypeMinimum, sshfpFPTypeMaximum, fpTypeStr, svcName)
+	}
+
+	fingerprint, ok := annotations[sshfpRecordTypeFingerprintAnnotationKey]
+	if !ok || fingerprint == "" {
+		return "", fmt.Errorf("must specify fingerprint value for SSHFP record. service %q", svcName)
+	}
+
+	return fmt.Sprintf("%d %d %s", algorithm, fpType, fingerprint), nil
+}
+
+// getTLSARecordTypeValuesFromAnnotations extracts the usage, selector, matching type and certificate
+// data of a TLSA record from the given annotations map.
+func getTLSARecordTypeValuesFromAnnotations(svcName string, annotations map[string]string) (string, error) {
+	usageStr, ok := annotations[tlsaRecordTypeUsageAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify usage value for TLSA record. service %q", svcName)
+	}
+	usage, err := strconv.ParseInt(usageStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("usage value must be int number, got %q. service %q", usageStr, svcName)
+	}
+	if usage < tlsaUsageMinimum || usage > tlsaUsageMaximum {
+		return "", fmt.Errorf("usage value must be between [%d, %d], got %q. service %q", tlsaUsageMinimum, tlsaUsageMaximum, usageStr, svcName)
+	}
+
+	selectorStr, ok := annotations[tlsaRecordTypeSelectorAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify selector value for TLSA record. service %q", svcName)
+	}
+	selector, err := strconv.ParseInt(selectorStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("selector value must be int number, got %q. service %q", selectorStr, svcName)
+	}
+	if selector < tlsaSelectorMinimum || selector > tlsaSelectorMaximum {
+		return "", fmt.Errorf("selector value must be between [%d, %d], got %q. service %q", tlsaSelectorMinimum, tlsaSelectorMaximum, selectorStr, svcName)
+	}
+
+	matchingTypeStr, ok := annotations[tlsaRecordTypeMatchingTypeAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("must specify matching-type value for TLSA record. service %q", svcName)
+	}
+	matchingType, err := strconv.ParseInt(matchingTypeStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("matching-type value must be int number, got %q. service %q", matchingTypeStr, svcName)
+	}
+	if matchingType < tlsaMatchingTypeMinimum || matchingType > tlsaMatchingTypeMaximum {
+		return "", fmt.Errorf("matching-type value must be between [%d, %d], got %q. service %q", tlsaMatchingTypeMinimum, tlsaMatchingTypeMaximum, matchingTypeStr, svcName)
+	}
+
+	certData, ok := annotations[tlsaRecordTypeCertDataAnnotationKey]
+	if !ok || certData == "" {
+		return "", fmt.Errorf("must specify cert-data value for TLSA record. service %q", svcName)
+	}
+
+	return fmt.Sprintf("%d %d %d %s", usage, selector, matchingType, certData), nil
+}
+
+// getScopedAnnotations looks up key scoped to recordType (e.g. "external-dns.alpha.kubernetes.io/
+// hostname.CNAME"), falling back to the un-suffixed key when the scoped form is absent. This lets a
+// single Service or Ingress carry different hostname/target/ttl values per record type it publishes,
+// e.g. an A record pointed at a LoadBalancer IP alongside a CNAME pointed elsewhere. An empty
+// recordType, or a recordType for which no scoped annotation exists, simply falls back to the
+// unscoped key rather than erroring.
+//
+// This package has no service.go/ingress.go in this tree, so wiring this helper through
+// endpointsFromService/endpointsFromIngress to actually produce the per-record-type overridden
+// endpoints is not done here — only the annotation lookup itself is implemented.
+func getScopedAnnotations(annotations map[string]string, key string, recordType endpoint.RecordType) (string, bool) {
+	if recordType != "" {
+		if value, ok := annotations[fmt.Sprintf("%s.%s", key, recordType)]; ok {
+			return value, true
+		}
+	}
+	value, ok := annotations[key]
+	return value, ok
+}
@@ -19,6 +19,7 @@ package source
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -82,13 +83,71 @@ func TestGetTTLFromAnnotations(t *testing.T) {
 		},
 	} {
 		t.Run(tc.title, func(t *testing.T) {
-			ttl, err := getTTLFromAnnotations(tc.annotations)
+			ttl, err := getTTLFromAnnotations(tc.annotations, nil)
 			assert.Equal(t, tc.expectedTTL, ttl)
 			assert.Equal(t, tc.expectedErr, err)
 		})
 	}
 }
 
+func TestGetTTLFromAnnotationsWithPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		policy      *TTLPolicy
+		expectedTTL endpoint.TTL
+	}{
+		{
+			title:       "value below min gets raised",
+			annotations: map[string]string{ttlAnnotationKey: "60"},
+			policy:      &TTLPolicy{Min: 5 * time.Minute},
+			expectedTTL: endpoint.TTL(300),
+		},
+		{
+			title:       "value above max gets lowered",
+			annotations: map[string]string{ttlAnnotationKey: "7200"},
+			policy:      &TTLPolicy{Max: time.Hour},
+			expectedTTL: endpoint.TTL(3600),
+		},
+		{
+			title:       "non-multiple gets rounded",
+			annotations: map[string]string{ttlAnnotationKey: "90"},
+			policy:      &TTLPolicy{RoundTo: 60 * time.Second},
+			expectedTTL: endpoint.TTL(120),
+		},
+		{
+			title:       "policy nil preserves current behavior",
+			annotations: map[string]string{ttlAnnotationKey: "90"},
+			policy:      nil,
+			expectedTTL: endpoint.TTL(90),
+		},
+		{
+			title:       "no TTL annotation uses policy default",
+			annotations: map[string]string{},
+			policy:      &TTLPolicy{Default: 10 * time.Minute},
+			expectedTTL: endpoint.TTL(600),
+		},
+		{
+			title:       "policy with only RoundTo still enforces the absolute minimum",
+			annotations: map[string]string{ttlAnnotationKey: "-120"},
+			policy:      &TTLPolicy{RoundTo: 60 * time.Second},
+			expectedTTL: endpoint.TTL(ttlMinimum),
+		},
+		{
+			title:       "policy with only RoundTo still enforces the absolute maximum",
+			annotations: map[string]string{ttlAnnotationKey: "99999999999"},
+			policy:      &TTLPolicy{RoundTo: 60 * time.Second},
+			expectedTTL: endpoint.TTL(ttlMaximum),
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			ttl, err := getTTLFromAnnotations(tc.annotations, tc.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTTL, ttl)
+		})
+	}
+}
+
 func TestGetSRVRecordTypeValuesFromAnnotations(t *testing.T) {
 	svcName := "testSvc"
 	for _, tc := range []struct {
@@ -204,19 +263,381 @@ func TestGetSRVRecordTypeValuesFromAnnotations(t *testing.T) {
 	}
 }
 
-func TestSuitableType(t *testing.T) {
+func TestGetSRVRecordTypeValuesListFromAnnotations(t *testing.T) {
+	svcName := "testSvc"
 	for _, tc := range []struct {
-		target, recordType, expected string
+		title          string
+		annotations    map[string]string
+		expectedValues []SRVValue
+		expectedErr    error
 	}{
-		{"8.8.8.8", "", "A"},
-		{"foo.example.org", "", "CNAME"},
-		{"bar.eu-central-1.elb.amazonaws.com", "", "CNAME"},
+		{
+			title: "no indexed keys falls back to legacy single-record annotations",
+			annotations: map[string]string{
+				srvRecordTypePriorityAnnotationKey: "5",
+				srvRecordTypeWeightAnnotationKey:   "7",
+				srvRecordTypePortAnnotationKey:     "443",
+				srvRecordTypeTargetAnnotationKey:   "sip.example.org",
+			},
+			expectedValues: []SRVValue{{Priority: 5, Weight: 7, Port: 443, Target: "sip.example.org"}},
+			expectedErr:    nil,
+		},
+		{
+			title: "legacy annotations missing target",
+			annotations: map[string]string{
+				srvRecordTypePriorityAnnotationKey: "5",
+				srvRecordTypeWeightAnnotationKey:   "7",
+				srvRecordTypePortAnnotationKey:     "443",
+			},
+			expectedValues: nil,
+			expectedErr:    fmt.Errorf("must specify target value for SRV record. service %q", svcName),
+		},
+		{
+			title: "one indexed entry",
+			annotations: map[string]string{
+				srvRecordTypePriorityAnnotationKey + ".0": "5",
+				srvRecordTypeWeightAnnotationKey + ".0":   "7",
+				srvRecordTypePortAnnotationKey + ".0":     "443",
+				srvRecordTypeTargetAnnotationKey + ".0":   "sip1.example.org",
+			},
+			expectedValues: []SRVValue{{Priority: 5, Weight: 7, Port: 443, Target: "sip1.example.org"}},
+			expectedErr:    nil,
+		},
+		{
+			title: "several indexed entries with a gap",
+			annotations: map[string]string{
+				srvRecordTypePriorityAnnotationKey + ".0": "5",
+				srvRecordTypeWeightAnnotationKey + ".0":   "7",
+				srvRecordTypePortAnnotationKey + ".0":     "443",
+				srvRecordTypeTargetAnnotationKey + ".0":   "sip1.example.org",
+				srvRecordTypePriorityAnnotationKey + ".2": "10",
+				srvRecordTypeWeightAnnotationKey + ".2":   "20",
+				srvRecordTypePortAnnotationKey + ".2":     "5060",
+				srvRecordTypeTargetAnnotationKey + ".2":   "sip3.example.org",
+			},
+			expectedValues: nil,
+			expectedErr:    fmt.Errorf("SRV record annotation indices must be contiguous starting at 0, missing index 1"),
+		},
+		{
+			title: "mixed legacy and indexed annotations",
+			annotations: map[string]string{
+				srvRecordTypePriorityAnnotationKey:        "5",
+				srvRecordTypeWeightAnnotationKey:          "7",
+				srvRecordTypePortAnnotationKey:            "443",
+				srvRecordTypeTargetAnnotationKey:          "sip.example.org",
+				srvRecordTypePriorityAnnotationKey + ".0": "5",
+				srvRecordTypeWeightAnnotationKey + ".0":   "7",
+				srvRecordTypePortAnnotationKey + ".0":     "443",
+				srvRecordTypeTargetAnnotationKey + ".0":   "sip1.example.org",
+			},
+			expectedValues: nil,
+			expectedErr:    fmt.Errorf("cannot mix indexed and non-indexed SRV record annotations. service %q", svcName),
+		},
 	} {
+		t.Run(tc.title, func(t *testing.T) {
+			values, err := getSRVRecordTypeValuesListFromAnnotations(svcName, tc.annotations)
+			assert.Equal(t, tc.expectedValues, values)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
 
-		recordType := suitableType(tc.target)
+func TestGetSRVRecordTypeTargetsFromAnnotations(t *testing.T) {
+	svcName := "testSvc"
+	for _, tc := range []struct {
+		title           string
+		annotations     map[string]string
+		expectedTargets []string
+		expectedErr     error
+	}{
+		{
+			title: "legacy single record is formatted as \"priority weight port target\"",
+			annotations: map[string]string{
+				srvRecordTypePriorityAnnotationKey: "5",
+				srvRecordTypeWeightAnnotationKey:   "7",
+				srvRecordTypePortAnnotationKey:     "443",
+				srvRecordTypeTargetAnnotationKey:   "sip.example.org",
+			},
+			expectedTargets: []string{"5 7 443 sip.example.org"},
+			expectedErr:     nil,
+		},
+		{
+			title: "multiple indexed records are each formatted as \"priority weight port target\"",
+			annotations: map[string]string{
+				srvRecordTypePriorityAnnotationKey + ".0": "5",
+				srvRecordTypeWeightAnnotationKey + ".0":   "7",
+				srvRecordTypePortAnnotationKey + ".0":     "443",
+				srvRecordTypeTargetAnnotationKey + ".0":   "sip1.example.org",
+				srvRecordTypePriorityAnnotationKey + ".1": "10",
+				srvRecordTypeWeightAnnotationKey + ".1":   "20",
+				srvRecordTypePortAnnotationKey + ".1":     "5060",
+				srvRecordTypeTargetAnnotationKey + ".1":   "sip2.example.org",
+			},
+			expectedTargets: []string{"5 7 443 sip1.example.org", "10 20 5060 sip2.example.org"},
+			expectedErr:     nil,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			targets, err := getSRVRecordTypeTargetsFromAnnotations(svcName, tc.annotations)
+			assert.Equal(t, tc.expectedTargets, targets)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
 
-		if recordType != tc.expected {
-			t.Errorf("expected %s, got %s", tc.expected, recordType)
-		}
+func TestGetRecordTypeValuesFromAnnotations(t *testing.T) {
+	svcName := "testSvc"
+	for _, tc := range []struct {
+		title          string
+		recordType     endpoint.RecordType
+		annotations    map[string]string
+		expectedTarget string
+		expectedErr    error
+	}{
+		{
+			title:          "MX missing exchange",
+			recordType:     endpoint.RecordTypeMX,
+			annotations:    map[string]string{mxRecordTypePreferenceAnnotationKey: "10"},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("must specify exchange value for MX record. service %q", svcName),
+		},
+		{
+			title:      "MX preference out of range",
+			recordType: endpoint.RecordTypeMX,
+			annotations: map[string]string{
+				mxRecordTypePreferenceAnnotationKey: "100000",
+				mxRecordTypeExchangeAnnotationKey:   "mail.example.org.",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("preference value must be between [%d, %d], got %q. service %q", mxPreferenceMinimum, mxPreferenceMaximum, "100000", svcName),
+		},
+		{
+			title:      "MX happy path",
+			recordType: endpoint.RecordTypeMX,
+			annotations: map[string]string{
+				mxRecordTypePreferenceAnnotationKey: "10",
+				mxRecordTypeExchangeAnnotationKey:   "mail.example.org.",
+			},
+			expectedTarget: "10 mail.example.org.",
+			expectedErr:    nil,
+		},
+		{
+			title:          "CAA missing tag",
+			recordType:     endpoint.RecordTypeCAA,
+			annotations:    map[string]string{caaRecordTypeFlagsAnnotationKey: "0"},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("must specify tag value for CAA record. service %q", svcName),
+		},
+		{
+			title:      "CAA flags out of range",
+			recordType: endpoint.RecordTypeCAA,
+			annotations: map[string]string{
+				caaRecordTypeFlagsAnnotationKey: "256",
+				caaRecordTypeTagAnnotationKey:   "issue",
+				caaRecordTypeValueAnnotationKey: "letsencrypt.org",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("flags value must be between [%d, %d], got %q. service %q", caaFlagsMinimum, caaFlagsMaximum, "256", svcName),
+		},
+		{
+			title:      "CAA happy path",
+			recordType: endpoint.RecordTypeCAA,
+			annotations: map[string]string{
+				caaRecordTypeFlagsAnnotationKey: "0",
+				caaRecordTypeTagAnnotationKey:   "issue",
+				caaRecordTypeValueAnnotationKey: "letsencrypt.org",
+			},
+			expectedTarget: `0 issue "letsencrypt.org"`,
+			expectedErr:    nil,
+		},
+		{
+			title:      "NAPTR missing replacement",
+			recordType: endpoint.RecordTypeNAPTR,
+			annotations: map[string]string{
+				naptrRecordTypeOrderAnnotationKey:      "100",
+				naptrRecordTypePreferenceAnnotationKey: "10",
+				naptrRecordTypeFlagsAnnotationKey:      "u",
+				naptrRecordTypeServiceAnnotationKey:    "E2U+sip",
+				naptrRecordTypeRegexpAnnotationKey:     "!^.*$!sip:info@example.org!",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("must specify replacement value for NAPTR record. service %q", svcName),
+		},
+		{
+			title:      "NAPTR order out of range",
+			recordType: endpoint.RecordTypeNAPTR,
+			annotations: map[string]string{
+				naptrRecordTypeOrderAnnotationKey:       "100000",
+				naptrRecordTypePreferenceAnnotationKey:  "10",
+				naptrRecordTypeFlagsAnnotationKey:       "u",
+				naptrRecordTypeServiceAnnotationKey:     "E2U+sip",
+				naptrRecordTypeRegexpAnnotationKey:      "!^.*$!sip:info@example.org!",
+				naptrRecordTypeReplacementAnnotationKey: ".",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("order value must be between [%d, %d], got %q. service %q", naptrOrderMinimum, naptrOrderMaximum, "100000", svcName),
+		},
+		{
+			title:      "NAPTR happy path",
+			recordType: endpoint.RecordTypeNAPTR,
+			annotations: map[string]string{
+				naptrRecordTypeOrderAnnotationKey:       "100",
+				naptrRecordTypePreferenceAnnotationKey:  "10",
+				naptrRecordTypeFlagsAnnotationKey:       "u",
+				naptrRecordTypeServiceAnnotationKey:     "E2U+sip",
+				naptrRecordTypeRegexpAnnotationKey:      "!^.*$!sip:info@example.org!",
+				naptrRecordTypeReplacementAnnotationKey: ".",
+			},
+			expectedTarget: `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.org!" .`,
+			expectedErr:    nil,
+		},
+		{
+			title:      "SSHFP missing fingerprint",
+			recordType: endpoint.RecordTypeSSHFP,
+			annotations: map[string]string{
+				sshfpRecordTypeAlgorithmAnnotationKey: "4",
+				sshfpRecordTypeFPTypeAnnotationKey:    "2",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("must specify fingerprint value for SSHFP record. service %q", svcName),
+		},
+		{
+			title:      "SSHFP algorithm out of range",
+			recordType: endpoint.RecordTypeSSHFP,
+			annotations: map[string]string{
+				sshfpRecordTypeAlgorithmAnnotationKey:   "5",
+				sshfpRecordTypeFPTypeAnnotationKey:      "2",
+				sshfpRecordTypeFingerprintAnnotationKey: "abcdef0123456789",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("algorithm value must be between [%d, %d], got %q. service %q", sshfpAlgorithmMinimum, sshfpAlgorithmMaximum, "5", svcName),
+		},
+		{
+			title:      "SSHFP happy path",
+			recordType: endpoint.RecordTypeSSHFP,
+			annotations: map[string]string{
+				sshfpRecordTypeAlgorithmAnnotationKey:   "4",
+				sshfpRecordTypeFPTypeAnnotationKey:      "2",
+				sshfpRecordTypeFingerprintAnnotationKey: "abcdef0123456789",
+			},
+			expectedTarget: "4 2 abcdef0123456789",
+			expectedErr:    nil,
+		},
+		{
+			title:      "TLSA missing cert-data",
+			recordType: endpoint.RecordTypeTLSA,
+			annotations: map[string]string{
+				tlsaRecordTypeUsageAnnotationKey:        "3",
+				tlsaRecordTypeSelectorAnnotationKey:     "1",
+				tlsaRecordTypeMatchingTypeAnnotationKey: "1",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("must specify cert-data value for TLSA record. service %q", svcName),
+		},
+		{
+			title:      "TLSA usage out of range",
+			recordType: endpoint.RecordTypeTLSA,
+			annotations: map[string]string{
+				tlsaRecordTypeUsageAnnotationKey:        "4",
+				tlsaRecordTypeSelectorAnnotationKey:     "1",
+				tlsaRecordTypeMatchingTypeAnnotationKey: "1",
+				tlsaRecordTypeCertDataAnnotationKey:     "abcdef0123456789",
+			},
+			expectedTarget: "",
+			expectedErr:    fmt.Errorf("usage value must be between [%d, %d], got %q. service %q", tlsaUsageMinimum, tlsaUsageMaximum, "4", svcName),
+		},
+		{
+			title:      "TLSA happy path",
+			recordType: endpoint.RecordTypeTLSA,
+			annotations: map[string]string{
+				tlsaRecordTypeUsageAnnotationKey:        "3",
+				tlsaRecordTypeSelectorAnnotationKey:     "1",
+				tlsaRecordTypeMatchingTypeAnnotationKey: "1",
+				tlsaRecordTypeCertDataAnnotationKey:     "abcdef0123456789",
+			},
+			expectedTarget: "3 1 1 abcdef0123456789",
+			expectedErr:    nil,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			target, err := getRecordTypeValuesFromAnnotations(tc.recordType, svcName, tc.annotations)
+			assert.Equal(t, tc.expectedTarget, target)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func TestGetScopedAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		title         string
+		annotations   map[string]string
+		key           string
+		recordType    endpoint.RecordType
+		expectedValue string
+		expectedOk    bool
+	}{
+		{
+			title:         "falls back to the unscoped key when no scoped form exists",
+			annotations:   map[string]string{hostnameAnnotationKey: "a.example.org"},
+			key:           hostnameAnnotationKey,
+			recordType:    endpoint.RecordTypeA,
+			expectedValue: "a.example.org",
+			expectedOk:    true,
+		},
+		{
+			title: "scoped value takes precedence over a conflicting unscoped value",
+			annotations: map[string]string{
+				hostnameAnnotationKey: "unscoped.example.org",
+				hostnameAnnotationKey + "." + string(endpoint.RecordTypeA): "scoped-a.example.org",
+			},
+			key:           hostnameAnnotationKey,
+			recordType:    endpoint.RecordTypeA,
+			expectedValue: "scoped-a.example.org",
+			expectedOk:    true,
+		},
+		{
+			title: "a different scoped suffix on the same object is unaffected",
+			annotations: map[string]string{
+				hostnameAnnotationKey + "." + string(endpoint.RecordTypeA):     "scoped-a.example.org",
+				hostnameAnnotationKey + "." + string(endpoint.RecordTypeCNAME): "scoped-cname.example.org",
+			},
+			key:           hostnameAnnotationKey,
+			recordType:    endpoint.RecordTypeCNAME,
+			expectedValue: "scoped-cname.example.org",
+			expectedOk:    true,
+		},
+		{
+			title:         "unknown record-type suffix is ignored, not an error",
+			annotations:   map[string]string{hostnameAnnotationKey: "a.example.org"},
+			key:           hostnameAnnotationKey,
+			recordType:    endpoint.RecordType("BOGUS"),
+			expectedValue: "a.example.org",
+			expectedOk:    true,
+		},
+		{
+			title:         "neither scoped nor unscoped key present",
+			annotations:   map[string]string{"foo": "bar"},
+			key:           hostnameAnnotationKey,
+			recordType:    endpoint.RecordTypeA,
+			expectedValue: "",
+			expectedOk:    false,
+		},
+		{
+			title: "scoped target annotation overrides the unscoped target for AAAA",
+			annotations: map[string]string{
+				targetAnnotationKey: "198.51.100.1",
+				targetAnnotationKey + "." + string(endpoint.RecordTypeAAAA): "2001:db8::1",
+			},
+			key:           targetAnnotationKey,
+			recordType:    endpoint.RecordTypeAAAA,
+			expectedValue: "2001:db8::1",
+			expectedOk:    true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			value, ok := getScopedAnnotations(tc.annotations, tc.key, tc.recordType)
+			assert.Equal(t, tc.expectedValue, value)
+			assert.Equal(t, tc.expectedOk, ok)
+		})
 	}
 }
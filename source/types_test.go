@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+)
+
+func TestSuitableType(t *testing.T) {
+	for _, tc := range []struct {
+		target, recordType, expected string
+	}{
+		{"8.8.8.8", "", "A"},
+		{"foo.example.org", "", "CNAME"},
+		{"bar.eu-central-1.elb.amazonaws.com", "", "CNAME"},
+		{"2001:db8::1", "", "AAAA"},
+		{"::1", "", "AAAA"},
+		{"::ffff:1.2.3.4", "", "A"},
+	} {
+
+		recordType := suitableType(tc.target)
+
+		if recordType != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, recordType)
+		}
+	}
+}
+
+// TestSuitableTypeDualStack covers the building block suitableType provides for a dual-stack
+// Service: given the two IPs such a Service would carry (e.g. spec.clusterIPs with
+// IPFamilies: [IPv4, IPv6], or a dual-stack LoadBalancer's ExternalIPs), each IP independently
+// resolves to the correct record type so a hostname can be backed by both an A and an AAAA
+// endpoint. This package has no service.go/ingress.go in this tree to wire that emission into, so
+// the test checks only the per-IP record types; it does not build *endpoint.Endpoint values for
+// the hostname.
+func TestSuitableTypeDualStack(t *testing.T) {
+	hostname := "dualstack.example.org"
+	ips := []string{"203.0.113.10", "2001:db8::10"}
+
+	var gotTypes []string
+	for _, ip := range ips {
+		gotTypes = append(gotTypes, suitableType(ip))
+	}
+
+	expectedTypes := []string{"A", "AAAA"}
+	for i, expected := range expectedTypes {
+		if gotTypes[i] != expected {
+			t.Errorf("dual-stack hostname %s: expected %s for %s, got %s", hostname, expected, ips[i], gotTypes[i])
+		}
+	}
+}